@@ -3,13 +3,21 @@ package tracker
 // TODO(mp) docs
 
 import (
+	"context"
 	"fmt"
+	"math/big"
+	"math/rand"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/vishvananda/netlink"
 
 	"github.com/weaveworks/weave/common"
@@ -17,11 +25,154 @@ import (
 	"github.com/weaveworks/weave/net/address"
 )
 
+// routeTableTagKey is the tag key used to select VPC route tables that weave
+// should manage, e.g. Name=tag:weave.works/managed, Values=[true]. It mirrors
+// the convention used by the Terraform AWS provider's tagsSchema().
+const routeTableTagKey = "tag:weave.works/managed"
+
+// defaultReconcilePeriod is how often the background reconciler compares the
+// managed route table(s) against the last known owned ranges when
+// AWSVPCTrackerConfig.ReconcilePeriod is left unset.
+const defaultReconcilePeriod = 5 * time.Minute
+
+// routeAlreadyExistsCode is the awserr.Error code CreateRoute returns when a
+// route for the given destination is already present.
+const routeAlreadyExistsCode = "RouteAlreadyExists"
+
+// routeLimitExceededCode is the awserr.Error code CreateRoute returns once a
+// route table has reached its maximum number of entries (50 by default).
+const routeLimitExceededCode = "RouteLimitExceeded"
+
+const (
+	// retryMaxAttempts bounds how many times a retryable EC2 call is
+	// attempted before giving up.
+	retryMaxAttempts = 5
+	// retryBaseBackoff is the wait before the first retry; it doubles on
+	// each subsequent attempt, up to AWSVPCTrackerConfig.RetryMaxBackoff.
+	retryBaseBackoff = 250 * time.Millisecond
+	// defaultRetryMaxBackoff caps the exponential backoff between retries
+	// when AWSVPCTrackerConfig.RetryMaxBackoff is left unset.
+	defaultRetryMaxBackoff = 30 * time.Second
+	// defaultCallTimeout bounds how long a single EC2 API call attempt may
+	// take when AWSVPCTrackerConfig.CallTimeout is left unset.
+	defaultCallTimeout = 10 * time.Second
+)
+
+// retryableErrorCodes are awserr.Error codes worth retrying with backoff:
+// they indicate transient throttling or server-side trouble rather than a
+// problem with the request itself. RequestCanceled is included because the
+// SDK reports t.callTimeout's per-attempt context deadline tripping under
+// this code, and a merely-slow call should be retried, not treated as fatal.
+var retryableErrorCodes = map[string]bool{
+	"RequestLimitExceeded": true,
+	"Throttling":           true,
+	"InternalError":        true,
+	"RequestCanceled":      true,
+}
+
+// isRetryableError reports whether aerr is worth retrying: a named code in
+// retryableErrorCodes, or any request that failed with an HTTP 5xx status,
+// which also indicates transient server-side trouble.
+func isRetryableError(aerr awserr.Error) bool {
+	if retryableErrorCodes[aerr.Code()] {
+		return true
+	}
+	if reqErr, ok := aerr.(awserr.RequestFailure); ok && reqErr.StatusCode() >= 500 {
+		return true
+	}
+	return false
+}
+
+// authErrorCodes are awserr.Error codes that mean the request is not, and
+// will never be, authorized - retrying only delays surfacing the problem.
+var authErrorCodes = map[string]bool{
+	"UnauthorizedOperation": true,
+	"AuthFailure":           true,
+}
+
+// requiredIAMActions maps the EC2 API action names used by AWSVPCTracker to
+// the IAM action required to call them, for inclusion in auth error messages.
+var requiredIAMActions = map[string]string{
+	"CreateRoute":               "ec2:CreateRoute",
+	"DeleteRoute":               "ec2:DeleteRoute",
+	"ReplaceRoute":              "ec2:ReplaceRoute",
+	"DescribeInstances":         "ec2:DescribeInstances",
+	"DescribeRouteTables":       "ec2:DescribeRouteTables",
+	"DescribeVpcs":              "ec2:DescribeVpcs",
+	"DescribeAccountAttributes": "ec2:DescribeAccountAttributes",
+}
+
+var routeLimitFallbackCount = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "weave",
+	Subsystem: "aws_vpc_tracker",
+	Name:      "route_limit_fallback_total",
+	Help:      "Number of times the VPC route table limit was hit and CIDR aggregation fallback engaged.",
+})
+
+func init() {
+	prometheus.MustRegister(routeLimitFallbackCount)
+}
+
+// ec2Client is the subset of *ec2.EC2 this tracker calls through retryEC2.
+// It exists so tests can exercise reconcileTable/applyAggregatedRoutes
+// against a hand-rolled fake instead of a live (or HTTP-stubbed) EC2 API;
+// *ec2.EC2 satisfies it without any change at the call sites.
+type ec2Client interface {
+	CreateRouteWithContext(aws.Context, *ec2.CreateRouteInput, ...request.Option) (*ec2.CreateRouteOutput, error)
+	DeleteRouteWithContext(aws.Context, *ec2.DeleteRouteInput, ...request.Option) (*ec2.DeleteRouteOutput, error)
+	ReplaceRouteWithContext(aws.Context, *ec2.ReplaceRouteInput, ...request.Option) (*ec2.ReplaceRouteOutput, error)
+	DescribeRouteTablesWithContext(aws.Context, *ec2.DescribeRouteTablesInput, ...request.Option) (*ec2.DescribeRouteTablesOutput, error)
+	DescribeInstancesWithContext(aws.Context, *ec2.DescribeInstancesInput, ...request.Option) (*ec2.DescribeInstancesOutput, error)
+	DescribeVpcsWithContext(aws.Context, *ec2.DescribeVpcsInput, ...request.Option) (*ec2.DescribeVpcsOutput, error)
+	DescribeAccountAttributesWithContext(aws.Context, *ec2.DescribeAccountAttributesInput, ...request.Option) (*ec2.DescribeAccountAttributesOutput, error)
+}
+
 type AWSVPCTracker struct {
-	ec2          *ec2.EC2
-	instanceID   string // EC2 Instance ID
-	routeTableID string // VPC Route Table ID
-	linkIndex    int    // The weave bridge link index
+	ec2           ec2Client
+	instanceID    string   // EC2 Instance ID
+	routeTableIDs []string // VPC Route Table IDs
+	linkIndex     int      // The weave bridge link index
+
+	reconcilePeriod time.Duration
+	stopCh          chan struct{}
+	doneCh          chan struct{}
+
+	retryMaxBackoff time.Duration
+	callTimeout     time.Duration
+
+	mu                sync.Mutex
+	ownedCIDRs        map[string]struct{} // last known set of CIDRs owned by this instance
+	aggregatingTables map[string]bool     // route tables that hit the route limit and are managed via aggregated CIDRs
+}
+
+// AWSVPCTrackerConfig selects which VPC route table(s) an AWSVPCTracker
+// manages. The zero value falls back to the single route table associated
+// with the instance's subnet (or the VPC's main route table), matching the
+// historical behaviour of NewAWSVPCTracker.
+type AWSVPCTrackerConfig struct {
+	// RouteTableIDs, when non-empty, is the explicit set of route tables to
+	// manage. Takes precedence over RouteTableTagValue.
+	RouteTableIDs []string
+	// RouteTableTagValue, when RouteTableIDs is empty, selects every route
+	// table tagged with Name=weave.works/managed, Values=[RouteTableTagValue].
+	RouteTableTagValue string
+	// EnableIPv6 opts into IPv6 CIDR route replication. When true,
+	// NewAWSVPCTrackerWithConfig validates at startup that the instance has
+	// an IPv6 address and the VPC has an IPv6 CIDR block associated, failing
+	// init with a descriptive error otherwise. Left false (the default),
+	// existing IPv4-only deployments are unaffected.
+	EnableIPv6 bool
+	// ReconcilePeriod controls how often the background reconciler repairs
+	// drift between the managed route table(s) and the last known owned
+	// ranges. Defaults to defaultReconcilePeriod when zero.
+	ReconcilePeriod time.Duration
+	// RetryMaxBackoff caps the exponential backoff between retried EC2
+	// calls. Defaults to defaultRetryMaxBackoff when zero.
+	RetryMaxBackoff time.Duration
+	// CallTimeout bounds how long a single EC2 API call attempt may take
+	// before it is abandoned and, if retryable, retried. Defaults to
+	// defaultCallTimeout when zero.
+	CallTimeout time.Duration
 }
 
 // NewAWSVPCTracker creates and initialises AWS VPC based tracker.
@@ -29,6 +180,14 @@ type AWSVPCTracker struct {
 // The tracker updates AWS VPC and host route tables when any changes to allocated
 // address ranges owned by a peer have been done.
 func NewAWSVPCTracker() (*AWSVPCTracker, error) {
+	return NewAWSVPCTrackerWithConfig(AWSVPCTrackerConfig{})
+}
+
+// NewAWSVPCTrackerWithConfig is like NewAWSVPCTracker but allows the set of
+// managed VPC route tables to be selected explicitly (config.RouteTableIDs)
+// or by tag (config.RouteTableTagValue), instead of relying on
+// detectRouteTableID to pick a single table.
+func NewAWSVPCTrackerWithConfig(config AWSVPCTrackerConfig) (*AWSVPCTracker, error) {
 	var (
 		err     error
 		session = session.New()
@@ -48,11 +207,25 @@ func NewAWSVPCTracker() (*AWSVPCTracker, error) {
 
 	t.ec2 = ec2.New(session, aws.NewConfig().WithRegion(region))
 
-	routeTableID, err := t.detectRouteTableID()
+	t.retryMaxBackoff = config.RetryMaxBackoff
+	if t.retryMaxBackoff <= 0 {
+		t.retryMaxBackoff = defaultRetryMaxBackoff
+	}
+	t.callTimeout = config.CallTimeout
+	if t.callTimeout <= 0 {
+		t.callTimeout = defaultCallTimeout
+	}
+
+	t.routeTableIDs, err = t.detectRouteTableIDs(config)
 	if err != nil {
 		return nil, err
 	}
-	t.routeTableID = *routeTableID
+
+	if config.EnableIPv6 {
+		if err := t.validateIPv6(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Detect Weave bridge link index
 	link, err := netlink.LinkByName(wnet.WeaveBridgeName)
@@ -61,12 +234,37 @@ func NewAWSVPCTracker() (*AWSVPCTracker, error) {
 	}
 	t.linkIndex = link.Attrs().Index
 
-	t.infof("AWSVPC has been initialized on %s instance for %s route table at %s region",
-		t.instanceID, t.routeTableID, region)
+	t.reconcilePeriod = config.ReconcilePeriod
+	if t.reconcilePeriod <= 0 {
+		t.reconcilePeriod = defaultReconcilePeriod
+	}
+	t.ownedCIDRs = make(map[string]struct{})
+	t.aggregatingTables = make(map[string]bool)
+	t.stopCh = make(chan struct{})
+	t.doneCh = make(chan struct{})
+
+	// Adopt any routes already targeting this instance (e.g. left behind by
+	// a previous run of weave) before the reconciler starts, so they are
+	// treated as owned rather than torn down as drift or recreated with a
+	// failing RouteAlreadyExists error.
+	if err := t.adoptExistingRoutes(); err != nil {
+		t.infof("reconciler: failed to adopt pre-existing routes: %s", err)
+	}
+	go t.reconcileLoop()
+
+	t.infof("AWSVPC has been initialized on %s instance for %v route table(s) at %s region",
+		t.instanceID, t.routeTableIDs, region)
 
 	return t, nil
 }
 
+// Stop terminates the background reconciler goroutine, blocking until it has
+// exited.
+func (t *AWSVPCTracker) Stop() {
+	close(t.stopCh)
+	<-t.doneCh
+}
+
 // HandleUpdate method updates the AWS VPC and the host route tables.
 func (t *AWSVPCTracker) HandleUpdate(prevRanges, currRanges []address.Range) error {
 	t.debugf("replacing %q entries by %q", prevRanges, currRanges)
@@ -77,34 +275,72 @@ func (t *AWSVPCTracker) HandleUpdate(prevRanges, currRanges []address.Range) err
 	// because of the 50 routes limit. However, in such case a container might
 	// not be reachable for a short period of time which is not a desired behavior.
 
+	aggregating := t.snapshotAggregatingTables()
+
 	// Add new entries
 	for _, cidr := range curr {
 		cidrStr := cidr.String()
 		t.debugf("adding route %s to %s", cidrStr, t.instanceID)
-		_, err := t.createVPCRoute(cidrStr)
-		// TODO(mp) check for 50 routes limit
-		// TODO(mp) maybe check for auth related errors
-		if err != nil {
-			return fmt.Errorf("createVPCRoutes failed: %s", err)
+		for _, routeTableID := range t.routeTableIDs {
+			if aggregating[routeTableID] {
+				// Already over the route limit on this table; applyAggregatedRoutes
+				// below will fold cidrStr into the covering set instead.
+				continue
+			}
+			_, err := t.createVPCRoute(routeTableID, cidrStr)
+			// Auth errors come back from retryEC2 already annotated with the
+			// required IAM action, and are returned below like any other error.
+			switch {
+			case isRouteLimitExceeded(err):
+				t.warnf("route limit reached on %s, falling back to CIDR aggregation (%s)",
+					routeTableID, t.describeRouteLimit())
+				routeLimitFallbackCount.Inc()
+				t.setAggregating(routeTableID)
+				aggregating[routeTableID] = true
+			case err != nil && !isRouteAlreadyExists(err):
+				return fmt.Errorf("createVPCRoutes failed: %s", err)
+			}
 		}
-		err = t.createHostRoute(cidrStr)
+		err := t.createHostRoute(cidrStr)
 		if err != nil {
 			return fmt.Errorf("createHostRoute failed: %s", err)
 		}
+		// Record ownership now, not after every cidr in curr has been
+		// processed: otherwise a reconcile() racing with this loop would see
+		// the route just created above but not yet find its CIDR in
+		// ownedCIDRs, and delete it as drift this instance no longer owns.
+		t.addOwnedCIDR(cidrStr)
 	}
 
 	// Remove obsolete entries
 	for _, cidr := range prev {
 		cidrStr := cidr.String()
 		t.debugf("removing %s route", cidrStr)
-		_, err := t.deleteVPCRoute(cidrStr)
-		if err != nil {
-			return fmt.Errorf("deleteVPCRoute failed: %s", err)
+		for _, routeTableID := range t.routeTableIDs {
+			if aggregating[routeTableID] {
+				// applyAggregatedRoutes below re-derives the covering set from
+				// currRanges, which already excludes cidrStr.
+				continue
+			}
+			_, err := t.deleteVPCRoute(routeTableID, cidrStr)
+			if err != nil {
+				return fmt.Errorf("deleteVPCRoute failed: %s", err)
+			}
 		}
-		err = t.deleteHostRoute(cidrStr)
+		err := t.deleteHostRoute(cidrStr)
 		if err != nil {
 			return fmt.Errorf("deleteHostRoute failed: %s", err)
 		}
+		// Drop ownership as soon as the route is gone, for the same reason
+		// addOwnedCIDR is called eagerly above: keep ownedCIDRs in sync with
+		// reality throughout, not just once this function returns.
+		t.removeOwnedCIDR(cidrStr)
+	}
+
+	for routeTableID := range aggregating {
+		if err := t.applyAggregatedRoutes(routeTableID, currRanges); err != nil {
+			return fmt.Errorf("CIDR aggregation fallback failed on %s: %s", routeTableID, err)
+		}
 	}
 
 	return nil
@@ -114,13 +350,23 @@ func (t *AWSVPCTracker) String() string {
 	return "awsvpc"
 }
 
-func (t *AWSVPCTracker) createVPCRoute(cidr string) (*ec2.CreateRouteOutput, error) {
+func (t *AWSVPCTracker) createVPCRoute(routeTableID, cidr string) (*ec2.CreateRouteOutput, error) {
 	route := &ec2.CreateRouteInput{
-		RouteTableId:         &t.routeTableID,
-		InstanceId:           &t.instanceID,
-		DestinationCidrBlock: &cidr,
+		RouteTableId: &routeTableID,
+		InstanceId:   &t.instanceID,
+	}
+	if isIPv6CIDR(cidr) {
+		route.DestinationIpv6CidrBlock = &cidr
+	} else {
+		route.DestinationCidrBlock = &cidr
 	}
-	return t.ec2.CreateRoute(route)
+	var out *ec2.CreateRouteOutput
+	err := t.retryEC2("CreateRoute", func(ctx aws.Context) error {
+		var err error
+		out, err = t.ec2.CreateRouteWithContext(ctx, route)
+		return err
+	})
+	return out, err
 }
 
 func (t *AWSVPCTracker) createHostRoute(cidr string) error {
@@ -131,17 +377,27 @@ func (t *AWSVPCTracker) createHostRoute(cidr string) error {
 	route := &netlink.Route{
 		LinkIndex: t.linkIndex,
 		Dst:       dst,
-		Scope:     netlink.SCOPE_LINK,
+		Scope:     hostRouteScope(dst),
 	}
 	return netlink.RouteAdd(route)
 }
 
-func (t *AWSVPCTracker) deleteVPCRoute(cidr string) (*ec2.DeleteRouteOutput, error) {
+func (t *AWSVPCTracker) deleteVPCRoute(routeTableID, cidr string) (*ec2.DeleteRouteOutput, error) {
 	route := &ec2.DeleteRouteInput{
-		RouteTableId:         &t.routeTableID,
-		DestinationCidrBlock: &cidr,
+		RouteTableId: &routeTableID,
+	}
+	if isIPv6CIDR(cidr) {
+		route.DestinationIpv6CidrBlock = &cidr
+	} else {
+		route.DestinationCidrBlock = &cidr
 	}
-	return t.ec2.DeleteRoute(route)
+	var out *ec2.DeleteRouteOutput
+	err := t.retryEC2("DeleteRoute", func(ctx aws.Context) error {
+		var err error
+		out, err = t.ec2.DeleteRouteWithContext(ctx, route)
+		return err
+	})
+	return out, err
 }
 
 func (t *AWSVPCTracker) deleteHostRoute(cidr string) error {
@@ -152,17 +408,125 @@ func (t *AWSVPCTracker) deleteHostRoute(cidr string) error {
 	route := &netlink.Route{
 		LinkIndex: t.linkIndex,
 		Dst:       dst,
-		Scope:     netlink.SCOPE_LINK,
+		Scope:     hostRouteScope(dst),
 	}
 	return netlink.RouteDel(route)
 }
 
+// detectRouteTableIDs resolves the set of VPC Route Table IDs the tracker
+// should manage, according to config. An explicit config.RouteTableIDs list
+// takes precedence, then config.RouteTableTagValue selects tables by tag,
+// and otherwise a single table is detected the same way detectRouteTableID
+// always has.
+func (t *AWSVPCTracker) detectRouteTableIDs(config AWSVPCTrackerConfig) ([]string, error) {
+	if len(config.RouteTableIDs) != 0 {
+		return config.RouteTableIDs, nil
+	}
+	if config.RouteTableTagValue != "" {
+		return t.detectRouteTableIDsByTag(config.RouteTableTagValue)
+	}
+	routeTableID, err := t.detectRouteTableID()
+	if err != nil {
+		return nil, err
+	}
+	return []string{*routeTableID}, nil
+}
+
+// detectRouteTableIDsByTag finds every VPC Route Table tagged with
+// Name=weave.works/managed, Values=[tagValue].
+func (t *AWSVPCTracker) detectRouteTableIDsByTag(tagValue string) ([]string, error) {
+	tablesParams := &ec2.DescribeRouteTablesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String(routeTableTagKey),
+				Values: []*string{aws.String(tagValue)},
+			},
+		},
+	}
+	var tablesResp *ec2.DescribeRouteTablesOutput
+	err := t.retryEC2("DescribeRouteTables", func(ctx aws.Context) error {
+		var err error
+		tablesResp, err = t.ec2.DescribeRouteTablesWithContext(ctx, tablesParams)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DescribeRouteTables failed: %s", err)
+	}
+	if len(tablesResp.RouteTables) == 0 {
+		return nil, fmt.Errorf("cannot find any route table tagged %s=%s", routeTableTagKey, tagValue)
+	}
+	routeTableIDs := make([]string, 0, len(tablesResp.RouteTables))
+	for _, table := range tablesResp.RouteTables {
+		routeTableIDs = append(routeTableIDs, *table.RouteTableId)
+	}
+	return routeTableIDs, nil
+}
+
+// validateIPv6 checks that the instance's VPC has an IPv6 CIDR block
+// associated and that the instance itself has an IPv6 address assigned,
+// so that IPv6 address ranges can later be programmed as VPC and host
+// routes.
+func (t *AWSVPCTracker) validateIPv6() error {
+	instancesParams := &ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(t.instanceID)},
+	}
+	var instancesResp *ec2.DescribeInstancesOutput
+	err := t.retryEC2("DescribeInstances", func(ctx aws.Context) error {
+		var err error
+		instancesResp, err = t.ec2.DescribeInstancesWithContext(ctx, instancesParams)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("DescribeInstances failed: %s", err)
+	}
+	if len(instancesResp.Reservations) == 0 ||
+		len(instancesResp.Reservations[0].Instances) == 0 {
+		return fmt.Errorf("cannot find %s instance within reservations", t.instanceID)
+	}
+	instance := instancesResp.Reservations[0].Instances[0]
+
+	hasIPv6 := false
+	for _, eni := range instance.NetworkInterfaces {
+		if len(eni.Ipv6Addresses) > 0 {
+			hasIPv6 = true
+			break
+		}
+	}
+	if !hasIPv6 {
+		return fmt.Errorf("instance %s has no IPv6 address on any of its network interfaces; "+
+			"assign one before relying on IPv6 routes", t.instanceID)
+	}
+
+	var vpcsResp *ec2.DescribeVpcsOutput
+	err = t.retryEC2("DescribeVpcs", func(ctx aws.Context) error {
+		var err error
+		vpcsResp, err = t.ec2.DescribeVpcsWithContext(ctx, &ec2.DescribeVpcsInput{
+			VpcIds: []*string{instance.VpcId},
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("DescribeVpcs failed: %s", err)
+	}
+	if len(vpcsResp.Vpcs) == 0 || len(vpcsResp.Vpcs[0].Ipv6CidrBlockAssociationSet) == 0 {
+		return fmt.Errorf("VPC %s has no IPv6 CIDR block associated; associate one before relying on IPv6 routes",
+			*instance.VpcId)
+	}
+
+	return nil
+}
+
 // detectRouteTableID detects AWS VPC Route Table ID of the given tracker instance.
 func (t *AWSVPCTracker) detectRouteTableID() (*string, error) {
 	instancesParams := &ec2.DescribeInstancesInput{
 		InstanceIds: []*string{aws.String(t.instanceID)},
 	}
-	instancesResp, err := t.ec2.DescribeInstances(instancesParams)
+	var instancesResp *ec2.DescribeInstancesOutput
+	err := t.retryEC2("DescribeInstances", func(ctx aws.Context) error {
+		var err error
+		instancesResp, err = t.ec2.DescribeInstancesWithContext(ctx, instancesParams)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("DescribeInstances failed: %s", err)
 	}
@@ -182,7 +546,12 @@ func (t *AWSVPCTracker) detectRouteTableID() (*string, error) {
 			},
 		},
 	}
-	tablesResp, err := t.ec2.DescribeRouteTables(tablesParams)
+	var tablesResp *ec2.DescribeRouteTablesOutput
+	err = t.retryEC2("DescribeRouteTables", func(ctx aws.Context) error {
+		var err error
+		tablesResp, err = t.ec2.DescribeRouteTablesWithContext(ctx, tablesParams)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("DescribeRouteTables failed: %s", err)
 	}
@@ -202,7 +571,11 @@ func (t *AWSVPCTracker) detectRouteTableID() (*string, error) {
 			},
 		},
 	}
-	tablesResp, err = t.ec2.DescribeRouteTables(tablesParams)
+	err = t.retryEC2("DescribeRouteTables", func(ctx aws.Context) error {
+		var err error
+		tablesResp, err = t.ec2.DescribeRouteTablesWithContext(ctx, tablesParams)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("DescribeRouteTables failed: %s", err)
 	}
@@ -213,6 +586,454 @@ func (t *AWSVPCTracker) detectRouteTableID() (*string, error) {
 	return nil, fmt.Errorf("cannot find routetable for %s instance", t.instanceID)
 }
 
+// addOwnedCIDR records cidr as owned by this instance. It is the
+// reconciler's source of truth for what should be present in the managed
+// route table(s), and must be called as soon as cidr's routes are created -
+// not batched until later - so a concurrent reconcile() never observes a
+// route this instance just created without also observing its ownership.
+func (t *AWSVPCTracker) addOwnedCIDR(cidr string) {
+	t.mu.Lock()
+	t.ownedCIDRs[cidr] = struct{}{}
+	t.mu.Unlock()
+}
+
+// removeOwnedCIDR forgets cidr, the mirror image of addOwnedCIDR: called as
+// soon as cidr's routes are torn down, so the reconciler stops expecting it.
+func (t *AWSVPCTracker) removeOwnedCIDR(cidr string) {
+	t.mu.Lock()
+	delete(t.ownedCIDRs, cidr)
+	t.mu.Unlock()
+}
+
+// snapshotOwnedCIDRs returns a copy of the last known set of owned CIDRs, so
+// the reconciler can inspect it without holding the lock during EC2 calls.
+func (t *AWSVPCTracker) snapshotOwnedCIDRs() map[string]struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot := make(map[string]struct{}, len(t.ownedCIDRs))
+	for cidr := range t.ownedCIDRs {
+		snapshot[cidr] = struct{}{}
+	}
+	return snapshot
+}
+
+// adoptExistingRoutes seeds ownedCIDRs from routes in the managed route
+// table(s) that already target this instance, so a restarting weave process
+// treats them as already-owned instead of the reconciler tearing them down
+// as drift.
+func (t *AWSVPCTracker) adoptExistingRoutes() error {
+	adopted := 0
+	for _, routeTableID := range t.routeTableIDs {
+		byCIDR, err := t.describeTableRoutes(routeTableID)
+		if err != nil {
+			return err
+		}
+		t.mu.Lock()
+		for cidr, route := range byCIDR {
+			if route.InstanceId != nil && *route.InstanceId == t.instanceID {
+				t.ownedCIDRs[cidr] = struct{}{}
+				adopted++
+			}
+		}
+		t.mu.Unlock()
+	}
+	if adopted > 0 {
+		t.infof("reconciler: adopted %d pre-existing route(s) for %s", adopted, t.instanceID)
+	}
+	return nil
+}
+
+// reconcileLoop periodically repairs drift between the managed route
+// table(s) and the last known owned ranges, until Stop is called. The
+// period is jittered on every iteration to avoid many nodes hammering the
+// EC2 API at the same moment, e.g. after a simultaneous restart.
+func (t *AWSVPCTracker) reconcileLoop() {
+	defer close(t.doneCh)
+	for {
+		select {
+		case <-time.After(jitter(t.reconcilePeriod)):
+			if err := t.reconcile(); err != nil {
+				t.infof("reconciler: %s", err)
+			}
+		case <-t.stopCh:
+			return
+		}
+	}
+}
+
+// reconcile compares the managed route table(s) against the last known
+// owned ranges and repairs any drift: it recreates routes that were removed
+// out-of-band, removes stale routes that this instance no longer owns, and
+// corrects routes whose target instance was overwritten by something else.
+func (t *AWSVPCTracker) reconcile() error {
+	owned := t.snapshotOwnedCIDRs()
+	aggregating := t.snapshotAggregatingTables()
+	for _, routeTableID := range t.routeTableIDs {
+		if err := t.reconcileTable(routeTableID, owned, aggregating[routeTableID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileTable reconciles a single route table against owned, the
+// granular per-range CIDRs this instance should have routes for. If
+// routeTableID is in aggregated mode, owned is first folded down to its
+// minimum covering set via aggregateCIDRs - the same set applyAggregatedRoutes
+// maintains - since describeTableRoutes will only ever see the aggregated
+// covering CIDRs there, never the granular ones.
+func (t *AWSVPCTracker) reconcileTable(routeTableID string, owned map[string]struct{}, aggregating bool) error {
+	byCIDR, err := t.describeTableRoutes(routeTableID)
+	if err != nil {
+		return err
+	}
+
+	wanted := owned
+	if aggregating {
+		cidrs := make([]string, 0, len(owned))
+		for cidr := range owned {
+			cidrs = append(cidrs, cidr)
+		}
+		wanted = make(map[string]struct{})
+		for _, cidr := range aggregateCIDRs(cidrs) {
+			wanted[cidr] = struct{}{}
+		}
+	}
+
+	for cidr := range wanted {
+		route, exists := byCIDR[cidr]
+		switch {
+		case !exists:
+			t.infof("reconciler: recreating missing route %s on %s", cidr, routeTableID)
+			if _, err := t.createVPCRoute(routeTableID, cidr); err != nil && !isRouteAlreadyExists(err) {
+				t.infof("reconciler: failed to recreate route %s on %s: %s", cidr, routeTableID, err)
+			}
+		case route.InstanceId == nil || *route.InstanceId != t.instanceID:
+			t.infof("reconciler: correcting route %s on %s, was targeting %s",
+				cidr, routeTableID, aws.StringValue(route.InstanceId))
+			if _, err := t.replaceVPCRoute(routeTableID, cidr); err != nil {
+				t.infof("reconciler: failed to correct route %s on %s: %s", cidr, routeTableID, err)
+			}
+		}
+	}
+
+	for cidr, route := range byCIDR {
+		if route.InstanceId == nil || *route.InstanceId != t.instanceID {
+			continue
+		}
+		if _, stillWanted := wanted[cidr]; stillWanted {
+			continue
+		}
+		t.infof("reconciler: removing stale route %s from %s", cidr, routeTableID)
+		if _, err := t.deleteVPCRoute(routeTableID, cidr); err != nil {
+			t.infof("reconciler: failed to remove stale route %s from %s: %s", cidr, routeTableID, err)
+		}
+	}
+
+	return nil
+}
+
+// describeTableRoutes returns every route in routeTableID that has a usable
+// IPv4 or IPv6 destination, keyed by CIDR string.
+func (t *AWSVPCTracker) describeTableRoutes(routeTableID string) (map[string]*ec2.Route, error) {
+	var resp *ec2.DescribeRouteTablesOutput
+	err := t.retryEC2("DescribeRouteTables", func(ctx aws.Context) error {
+		var err error
+		resp, err = t.ec2.DescribeRouteTablesWithContext(ctx, &ec2.DescribeRouteTablesInput{
+			RouteTableIds: []*string{&routeTableID},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DescribeRouteTables failed: %s", err)
+	}
+	if len(resp.RouteTables) == 0 {
+		return nil, fmt.Errorf("route table %s not found", routeTableID)
+	}
+	byCIDR := make(map[string]*ec2.Route)
+	for _, route := range resp.RouteTables[0].Routes {
+		if cidr := routeDestination(route); cidr != "" {
+			byCIDR[cidr] = route
+		}
+	}
+	return byCIDR, nil
+}
+
+func (t *AWSVPCTracker) replaceVPCRoute(routeTableID, cidr string) (*ec2.ReplaceRouteOutput, error) {
+	route := &ec2.ReplaceRouteInput{
+		RouteTableId: &routeTableID,
+		InstanceId:   &t.instanceID,
+	}
+	if isIPv6CIDR(cidr) {
+		route.DestinationIpv6CidrBlock = &cidr
+	} else {
+		route.DestinationCidrBlock = &cidr
+	}
+	var out *ec2.ReplaceRouteOutput
+	err := t.retryEC2("ReplaceRoute", func(ctx aws.Context) error {
+		var err error
+		out, err = t.ec2.ReplaceRouteWithContext(ctx, route)
+		return err
+	})
+	return out, err
+}
+
+// routeDestination returns route's IPv4 or IPv6 destination CIDR, or "" if
+// it has neither (e.g. a prefix-list or gateway-only route we don't track).
+func routeDestination(route *ec2.Route) string {
+	if route.DestinationCidrBlock != nil && *route.DestinationCidrBlock != "" {
+		return *route.DestinationCidrBlock
+	}
+	if route.DestinationIpv6CidrBlock != nil && *route.DestinationIpv6CidrBlock != "" {
+		return *route.DestinationIpv6CidrBlock
+	}
+	return ""
+}
+
+// jitter returns d adjusted by up to +/-10%, so that many nodes reconciling
+// on the same period don't all call the EC2 API at the same instant.
+func jitter(d time.Duration) time.Duration {
+	spread := d / 5
+	if spread <= 0 {
+		return d
+	}
+	return d - spread/2 + time.Duration(rand.Int63n(int64(spread)))
+}
+
+// isRouteAlreadyExists reports whether err is the awserr.Error EC2 returns
+// when CreateRoute is called for a destination that already has a route.
+func isRouteAlreadyExists(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == routeAlreadyExistsCode
+}
+
+// isRouteLimitExceeded reports whether err is the awserr.Error EC2 returns
+// once a route table has reached its maximum number of entries.
+func isRouteLimitExceeded(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == routeLimitExceededCode
+}
+
+// retryEC2 runs call, retrying with jittered exponential backoff (capped at
+// t.retryMaxBackoff) while it fails with a transient awserr.Error such as
+// throttling, up to retryMaxAttempts. Each attempt gets its own
+// t.callTimeout deadline via the aws.Context passed to call. Auth errors are
+// surfaced immediately, annotated with the IAM action action requires.
+func (t *AWSVPCTracker) retryEC2(action string, call func(ctx aws.Context) error) error {
+	backoff := retryBaseBackoff
+	var lastErr error
+	for attempt := 1; attempt <= retryMaxAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), t.callTimeout)
+		lastErr = call(ctx)
+		cancel()
+
+		if lastErr == nil {
+			return nil
+		}
+
+		aerr, ok := lastErr.(awserr.Error)
+		if !ok {
+			return lastErr
+		}
+		if authErrorCodes[aerr.Code()] {
+			return fmt.Errorf("%s failed: %s (requires IAM permission %s)", action, aerr, requiredIAMActions[action])
+		}
+		if !isRetryableError(aerr) {
+			// Not one we retry (e.g. RouteAlreadyExists, RouteLimitExceeded);
+			// return it as-is so callers can type-assert on its awserr.Code().
+			return lastErr
+		}
+		if attempt == retryMaxAttempts {
+			return fmt.Errorf("%s failed after %d attempts: %s", action, retryMaxAttempts, lastErr)
+		}
+
+		wait := jitter(backoff)
+		if wait > t.retryMaxBackoff {
+			wait = t.retryMaxBackoff
+		}
+		t.warnf("%s failed (attempt %d/%d), retrying in %s: %s", action, attempt, retryMaxAttempts, wait, aerr)
+		time.Sleep(wait)
+
+		backoff *= 2
+		if backoff > t.retryMaxBackoff {
+			backoff = t.retryMaxBackoff
+		}
+	}
+	return lastErr
+}
+
+// snapshotAggregatingTables returns a copy of the set of route tables
+// currently managed via CIDR aggregation, rather than per-range routes.
+func (t *AWSVPCTracker) snapshotAggregatingTables() map[string]bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot := make(map[string]bool, len(t.aggregatingTables))
+	for routeTableID, v := range t.aggregatingTables {
+		snapshot[routeTableID] = v
+	}
+	return snapshot
+}
+
+// setAggregating marks routeTableID as managed via CIDR aggregation from now
+// on. The decision is sticky: once a table has hit the route limit it stays
+// in aggregated mode, since the raw per-range route count that caused the
+// overflow doesn't shrink on its own.
+func (t *AWSVPCTracker) setAggregating(routeTableID string) {
+	t.mu.Lock()
+	t.aggregatingTables[routeTableID] = true
+	t.mu.Unlock()
+}
+
+// describeRouteLimit returns a human-readable description of the account's
+// configured max routes per table, for use in the fallback warning log. It
+// falls back to a generic message if the attribute cannot be read.
+func (t *AWSVPCTracker) describeRouteLimit() string {
+	var resp *ec2.DescribeAccountAttributesOutput
+	err := t.retryEC2("DescribeAccountAttributes", func(ctx aws.Context) error {
+		var err error
+		resp, err = t.ec2.DescribeAccountAttributesWithContext(ctx, &ec2.DescribeAccountAttributesInput{
+			AttributeNames: []*string{aws.String("vpc-max-entries-per-route-table")},
+		})
+		return err
+	})
+	if err != nil || len(resp.AccountAttributes) == 0 || len(resp.AccountAttributes[0].AttributeValues) == 0 {
+		return "raise the vpc-max-entries-per-route-table account limit if this persists"
+	}
+	return fmt.Sprintf("account limit is %s entries per route table; raise it if this persists",
+		*resp.AccountAttributes[0].AttributeValues[0].AttributeValue)
+}
+
+// applyAggregatedRoutes replaces the per-range routes this instance owns on
+// routeTableID with a minimum covering set of aggregated CIDRs computed from
+// currRanges, creating new aggregated routes and removing whatever routes
+// (granular or previously aggregated) are no longer part of the cover.
+func (t *AWSVPCTracker) applyAggregatedRoutes(routeTableID string, currRanges []address.Range) error {
+	owned := address.NewCIDRs(currRanges)
+	cidrs := make([]string, 0, len(owned))
+	for _, c := range owned {
+		cidrs = append(cidrs, c.String())
+	}
+	desired := aggregateCIDRs(cidrs)
+	desiredSet := make(map[string]bool, len(desired))
+	for _, cidr := range desired {
+		desiredSet[cidr] = true
+	}
+
+	existing, err := t.describeTableRoutes(routeTableID)
+	if err != nil {
+		return err
+	}
+
+	for _, cidr := range desired {
+		if route, ok := existing[cidr]; ok && route.InstanceId != nil && *route.InstanceId == t.instanceID {
+			continue
+		}
+		if _, err := t.createVPCRoute(routeTableID, cidr); err != nil && !isRouteAlreadyExists(err) {
+			return fmt.Errorf("createVPCRoute failed: %s", err)
+		}
+	}
+	for cidr, route := range existing {
+		if route.InstanceId == nil || *route.InstanceId != t.instanceID {
+			continue
+		}
+		if desiredSet[cidr] {
+			continue
+		}
+		if _, err := t.deleteVPCRoute(routeTableID, cidr); err != nil {
+			return fmt.Errorf("deleteVPCRoute failed: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// aggregateCIDRs computes a minimum covering set for cidrs by repeatedly
+// combining sibling /n blocks into a single /n-1 block, within each address
+// family, until no more merges are possible.
+func aggregateCIDRs(cidrs []string) []string {
+	var v4, v6 []cidrBlock
+	for _, c := range cidrs {
+		ip, ipnet, err := net.ParseCIDR(c)
+		if err != nil {
+			continue
+		}
+		ones, bits := ipnet.Mask.Size()
+		b := cidrBlock{base: new(big.Int).SetBytes(ip.Mask(ipnet.Mask)), prefixLen: ones, totalBits: bits}
+		if bits == net.IPv4len*8 {
+			v4 = append(v4, b)
+		} else {
+			v6 = append(v6, b)
+		}
+	}
+	result := mergeCIDRBlocks(v4)
+	result = append(result, mergeCIDRBlocks(v6)...)
+	return result
+}
+
+// cidrBlock is a CIDR block represented as its masked base address (as an
+// unsigned integer), prefix length, and total address width (32 or 128).
+type cidrBlock struct {
+	base      *big.Int
+	prefixLen int
+	totalBits int
+}
+
+func (b cidrBlock) key() string {
+	return fmt.Sprintf("%d/%d", b.base, b.prefixLen)
+}
+
+// sibling returns the block that, together with b, exactly fills their
+// shared /prefixLen-1 parent block.
+func (b cidrBlock) sibling() cidrBlock {
+	blockSize := new(big.Int).Lsh(big.NewInt(1), uint(b.totalBits-b.prefixLen))
+	siblingBase := new(big.Int).Xor(b.base, blockSize)
+	return cidrBlock{base: siblingBase, prefixLen: b.prefixLen, totalBits: b.totalBits}
+}
+
+// mergeCIDRBlocks repeatedly merges sibling pairs at the same prefix length
+// into their common, one-bit-shorter parent until a fixed point is reached,
+// then renders what remains as CIDR strings.
+func mergeCIDRBlocks(blocks []cidrBlock) []string {
+	set := make(map[string]cidrBlock, len(blocks))
+	for _, b := range blocks {
+		set[b.key()] = b
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for key, b := range set {
+			if b.prefixLen == 0 {
+				continue
+			}
+			sib := b.sibling()
+			sibKey := sib.key()
+			if _, ok := set[sibKey]; !ok {
+				continue
+			}
+			parentBase := new(big.Int).And(b.base, sib.base)
+			parent := cidrBlock{base: parentBase, prefixLen: b.prefixLen - 1, totalBits: b.totalBits}
+			delete(set, key)
+			delete(set, sibKey)
+			set[parent.key()] = parent
+			changed = true
+		}
+	}
+
+	result := make([]string, 0, len(set))
+	for _, b := range set {
+		result = append(result, b.cidrString())
+	}
+	return result
+}
+
+func (b cidrBlock) cidrString() string {
+	byteLen := b.totalBits / 8
+	ipBytes := b.base.Bytes()
+	padded := make([]byte, byteLen)
+	copy(padded[byteLen-len(ipBytes):], ipBytes)
+	return fmt.Sprintf("%s/%d", net.IP(padded).String(), b.prefixLen)
+}
+
 func (t *AWSVPCTracker) debugf(fmt string, args ...interface{}) {
 	common.Log.Debugf("[tracker] "+fmt, args...)
 }
@@ -221,6 +1042,10 @@ func (t *AWSVPCTracker) infof(fmt string, args ...interface{}) {
 	common.Log.Infof("[tracker] "+fmt, args...)
 }
 
+func (t *AWSVPCTracker) warnf(fmt string, args ...interface{}) {
+	common.Log.Warningf("[tracker] "+fmt, args...)
+}
+
 // Helpers
 
 // removeCommon filters out CIDR ranges which are contained in both a and b slices.
@@ -255,4 +1080,23 @@ func parseCIDR(cidr string) (*net.IPNet, error) {
 	ipnet.IP = ip
 
 	return ipnet, nil
+}
+
+// isIPv6CIDR reports whether cidr is an IPv6 CIDR block, as opposed to IPv4.
+func isIPv6CIDR(cidr string) bool {
+	ip, _, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	return ip.To4() == nil
+}
+
+// hostRouteScope picks the netlink route scope appropriate for dst: link
+// scope for IPv4 (matching the existing weave bridge host routes), universe
+// scope for IPv6, which has no concept of a "link-local" unicast route.
+func hostRouteScope(dst *net.IPNet) netlink.Scope {
+	if dst.IP.To4() == nil {
+		return netlink.SCOPE_UNIVERSE
+	}
+	return netlink.SCOPE_LINK
 }
\ No newline at end of file
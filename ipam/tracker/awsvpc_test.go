@@ -0,0 +1,118 @@
+package tracker
+
+import (
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func sortedStrings(ss []string) []string {
+	out := make([]string, len(ss))
+	copy(out, ss)
+	sort.Strings(out)
+	return out
+}
+
+func TestAggregateCIDRs(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{
+			name: "single block is left alone",
+			in:   []string{"10.0.0.0/24"},
+			want: []string{"10.0.0.0/24"},
+		},
+		{
+			name: "sibling /24s merge into a /23",
+			in:   []string{"10.0.0.0/24", "10.0.1.0/24"},
+			want: []string{"10.0.0.0/23"},
+		},
+		{
+			name: "non-sibling /24s do not merge",
+			in:   []string{"10.0.0.0/24", "10.0.2.0/24"},
+			want: []string{"10.0.0.0/24", "10.0.2.0/24"},
+		},
+		{
+			name: "merging cascades across prefix lengths",
+			in:   []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"},
+			want: []string{"10.0.0.0/22"},
+		},
+		{
+			name: "a missing sibling blocks the cascade partway up",
+			in:   []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"},
+			want: []string{"10.0.0.0/23", "10.0.2.0/24"},
+		},
+		{
+			name: "v4 and v6 blocks are aggregated independently",
+			in:   []string{"10.0.0.0/24", "10.0.1.0/24", "fd00::/64", "fd00:0:0:1::/64"},
+			want: []string{"10.0.0.0/23", "fd00::/63"},
+		},
+		{
+			name: "invalid entries are skipped rather than erroring",
+			in:   []string{"not-a-cidr", "10.0.0.0/24"},
+			want: []string{"10.0.0.0/24"},
+		},
+		{
+			name: "empty input yields empty output",
+			in:   nil,
+			want: []string{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := aggregateCIDRs(tc.in)
+			if !reflect.DeepEqual(sortedStrings(got), sortedStrings(tc.want)) {
+				t.Errorf("aggregateCIDRs(%v) = %v, want %v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func mustCIDRBlock(t *testing.T, cidr string) cidrBlock {
+	t.Helper()
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatalf("net.ParseCIDR(%q): %s", cidr, err)
+	}
+	ones, bits := ipnet.Mask.Size()
+	return cidrBlock{base: new(big.Int).SetBytes(ip.Mask(ipnet.Mask)), prefixLen: ones, totalBits: bits}
+}
+
+func TestCIDRBlockSibling(t *testing.T) {
+	b := mustCIDRBlock(t, "10.0.0.0/24")
+	sib := b.sibling()
+	if got := sib.cidrString(); got != "10.0.1.0/24" {
+		t.Errorf("sibling of 10.0.0.0/24 = %s, want 10.0.1.0/24", got)
+	}
+	// sibling is its own inverse
+	if got := sib.sibling().cidrString(); got != "10.0.0.0/24" {
+		t.Errorf("sibling(sibling(10.0.0.0/24)) = %s, want 10.0.0.0/24", got)
+	}
+}
+
+func TestMergeCIDRBlocks(t *testing.T) {
+	blocks := []cidrBlock{
+		mustCIDRBlock(t, "10.0.0.0/24"),
+		mustCIDRBlock(t, "10.0.1.0/24"),
+	}
+	got := mergeCIDRBlocks(blocks)
+	want := []string{"10.0.0.0/23"}
+	if !reflect.DeepEqual(sortedStrings(got), sortedStrings(want)) {
+		t.Errorf("mergeCIDRBlocks(%v) = %v, want %v", blocks, got, want)
+	}
+}
+
+func TestMergeCIDRBlocksNoSiblings(t *testing.T) {
+	blocks := []cidrBlock{
+		mustCIDRBlock(t, "10.0.0.0/24"),
+		mustCIDRBlock(t, "10.0.5.0/24"),
+	}
+	got := sortedStrings(mergeCIDRBlocks(blocks))
+	want := []string{"10.0.0.0/24", "10.0.5.0/24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeCIDRBlocks(%v) = %v, want %v", blocks, got, want)
+	}
+}
@@ -0,0 +1,178 @@
+package tracker
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// fakeEC2Client is a hand-rolled, in-memory stand-in for a single route
+// table's worth of *ec2.EC2, used to drive reconcileTable/applyAggregatedRoutes
+// without a live (or HTTP-stubbed) EC2 API. It records every mutating call it
+// receives so tests can assert exactly what the reconciler did.
+type fakeEC2Client struct {
+	mu     sync.Mutex
+	routes map[string]*ec2.Route
+	calls  []string
+}
+
+func newFakeEC2Client(routes map[string]*ec2.Route) *fakeEC2Client {
+	return &fakeEC2Client{routes: routes}
+}
+
+func (f *fakeEC2Client) recordedCalls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, len(f.calls))
+	copy(out, f.calls)
+	return out
+}
+
+func destinationOf(cidrBlock, ipv6CidrBlock *string) string {
+	if cidrBlock != nil && *cidrBlock != "" {
+		return *cidrBlock
+	}
+	return aws.StringValue(ipv6CidrBlock)
+}
+
+func (f *fakeEC2Client) CreateRouteWithContext(_ aws.Context, in *ec2.CreateRouteInput, _ ...request.Option) (*ec2.CreateRouteOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cidr := destinationOf(in.DestinationCidrBlock, in.DestinationIpv6CidrBlock)
+	f.calls = append(f.calls, "CreateRoute "+cidr)
+	f.routes[cidr] = &ec2.Route{
+		DestinationCidrBlock:     in.DestinationCidrBlock,
+		DestinationIpv6CidrBlock: in.DestinationIpv6CidrBlock,
+		InstanceId:               in.InstanceId,
+		State:                    aws.String("active"),
+	}
+	return &ec2.CreateRouteOutput{Return: aws.Bool(true)}, nil
+}
+
+func (f *fakeEC2Client) DeleteRouteWithContext(_ aws.Context, in *ec2.DeleteRouteInput, _ ...request.Option) (*ec2.DeleteRouteOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cidr := destinationOf(in.DestinationCidrBlock, in.DestinationIpv6CidrBlock)
+	f.calls = append(f.calls, "DeleteRoute "+cidr)
+	delete(f.routes, cidr)
+	return &ec2.DeleteRouteOutput{}, nil
+}
+
+func (f *fakeEC2Client) ReplaceRouteWithContext(_ aws.Context, in *ec2.ReplaceRouteInput, _ ...request.Option) (*ec2.ReplaceRouteOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cidr := destinationOf(in.DestinationCidrBlock, in.DestinationIpv6CidrBlock)
+	f.calls = append(f.calls, "ReplaceRoute "+cidr)
+	if route, ok := f.routes[cidr]; ok {
+		route.InstanceId = in.InstanceId
+	}
+	return &ec2.ReplaceRouteOutput{}, nil
+}
+
+func (f *fakeEC2Client) DescribeRouteTablesWithContext(_ aws.Context, _ *ec2.DescribeRouteTablesInput, _ ...request.Option) (*ec2.DescribeRouteTablesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	routes := make([]*ec2.Route, 0, len(f.routes))
+	for _, route := range f.routes {
+		routes = append(routes, route)
+	}
+	return &ec2.DescribeRouteTablesOutput{
+		RouteTables: []*ec2.RouteTable{{RouteTableId: aws.String("rtb-test"), Routes: routes}},
+	}, nil
+}
+
+// The remaining ec2Client methods are not exercised by the reconciler tests
+// below; they only need to satisfy the interface.
+func (f *fakeEC2Client) DescribeInstancesWithContext(aws.Context, *ec2.DescribeInstancesInput, ...request.Option) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{}, nil
+}
+func (f *fakeEC2Client) DescribeVpcsWithContext(aws.Context, *ec2.DescribeVpcsInput, ...request.Option) (*ec2.DescribeVpcsOutput, error) {
+	return &ec2.DescribeVpcsOutput{}, nil
+}
+func (f *fakeEC2Client) DescribeAccountAttributesWithContext(aws.Context, *ec2.DescribeAccountAttributesInput, ...request.Option) (*ec2.DescribeAccountAttributesOutput, error) {
+	return &ec2.DescribeAccountAttributesOutput{}, nil
+}
+
+func newTestTracker(ec2 ec2Client) *AWSVPCTracker {
+	return &AWSVPCTracker{
+		ec2:               ec2,
+		instanceID:        "i-test",
+		routeTableIDs:     []string{"rtb-test"},
+		retryMaxBackoff:   time.Millisecond,
+		callTimeout:       time.Second,
+		ownedCIDRs:        map[string]struct{}{},
+		aggregatingTables: map[string]bool{},
+	}
+}
+
+// TestReconcileTableLeavesUpToDateAggregatedRouteAlone proves a table already
+// in aggregated mode, whose single covering route matches what its owned
+// ranges aggregate down to, survives a reconcile pass unchanged: reconcile
+// must diff against the aggregated covering set, not the granular owned
+// CIDRs, or it will see the covering route as "not owned" and delete it.
+func TestReconcileTableLeavesUpToDateAggregatedRouteAlone(t *testing.T) {
+	fake := newFakeEC2Client(map[string]*ec2.Route{
+		"10.0.0.0/22": {DestinationCidrBlock: aws.String("10.0.0.0/22"), InstanceId: aws.String("i-test"), State: aws.String("active")},
+	})
+	tr := newTestTracker(fake)
+	for _, cidr := range []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"} {
+		tr.addOwnedCIDR(cidr)
+	}
+	tr.setAggregating("rtb-test")
+
+	if err := tr.reconcile(); err != nil {
+		t.Fatalf("reconcile: %s", err)
+	}
+
+	if calls := fake.recordedCalls(); len(calls) != 0 {
+		t.Errorf("reconcile mutated an up-to-date aggregated table: %v", calls)
+	}
+}
+
+// TestReconcileTableRecreatesMissingAggregatedRoute proves that when the
+// aggregated covering route is missing, reconcile recreates exactly that one
+// covering route - not every granular owned CIDR individually, which would
+// just re-hit the route limit that caused aggregation in the first place.
+func TestReconcileTableRecreatesMissingAggregatedRoute(t *testing.T) {
+	fake := newFakeEC2Client(map[string]*ec2.Route{})
+	tr := newTestTracker(fake)
+	for _, cidr := range []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"} {
+		tr.addOwnedCIDR(cidr)
+	}
+	tr.setAggregating("rtb-test")
+
+	if err := tr.reconcile(); err != nil {
+		t.Fatalf("reconcile: %s", err)
+	}
+
+	calls := fake.recordedCalls()
+	if len(calls) != 1 || calls[0] != "CreateRoute 10.0.0.0/22" {
+		t.Errorf("reconcile calls = %v, want exactly [CreateRoute 10.0.0.0/22]", calls)
+	}
+}
+
+// TestReconcileTableRemovesStaleGranularRouteOnNonAggregatedTable is the
+// non-aggregated counterpart: a table not in aggregated mode still reconciles
+// against the raw granular owned set, deleting routes this instance no
+// longer owns.
+func TestReconcileTableRemovesStaleGranularRouteOnNonAggregatedTable(t *testing.T) {
+	fake := newFakeEC2Client(map[string]*ec2.Route{
+		"10.0.0.0/24": {DestinationCidrBlock: aws.String("10.0.0.0/24"), InstanceId: aws.String("i-test"), State: aws.String("active")},
+		"10.0.9.0/24": {DestinationCidrBlock: aws.String("10.0.9.0/24"), InstanceId: aws.String("i-test"), State: aws.String("active")},
+	})
+	tr := newTestTracker(fake)
+	tr.addOwnedCIDR("10.0.0.0/24")
+
+	if err := tr.reconcile(); err != nil {
+		t.Fatalf("reconcile: %s", err)
+	}
+
+	calls := fake.recordedCalls()
+	if len(calls) != 1 || calls[0] != "DeleteRoute 10.0.9.0/24" {
+		t.Errorf("reconcile calls = %v, want exactly [DeleteRoute 10.0.9.0/24]", calls)
+	}
+}
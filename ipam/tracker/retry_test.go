@@ -0,0 +1,176 @@
+package tracker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+func newRetryTestTracker() *AWSVPCTracker {
+	return &AWSVPCTracker{
+		retryMaxBackoff: time.Millisecond,
+		callTimeout:     time.Second,
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  awserr.Error
+		want bool
+	}{
+		{"RequestLimitExceeded", awserr.New("RequestLimitExceeded", "slow down", nil), true},
+		{"Throttling", awserr.New("Throttling", "slow down", nil), true},
+		{"InternalError", awserr.New("InternalError", "oops", nil), true},
+		{"RequestCanceled", awserr.New("RequestCanceled", "context deadline exceeded", nil), true},
+		{"5xx status via RequestFailure", awserr.NewRequestFailure(
+			awserr.New("ServiceUnavailable", "try again", nil), 503, "req-1"), true},
+		{"other 5xx code not in the named set", awserr.NewRequestFailure(
+			awserr.New("SomeOtherCode", "try again", nil), 500, "req-2"), true},
+		{"4xx RequestFailure is not retryable", awserr.NewRequestFailure(
+			awserr.New("RouteAlreadyExists", "exists", nil), 400, "req-3"), false},
+		{"unrelated error code", awserr.New("RouteAlreadyExists", "exists", nil), false},
+		{"auth error code", awserr.New("UnauthorizedOperation", "no", nil), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRetryableError(tc.err); got != tc.want {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryEC2SucceedsWithoutRetry(t *testing.T) {
+	tr := newRetryTestTracker()
+	attempts := 0
+	err := tr.retryEC2("CreateRoute", func(ctx aws.Context) error {
+		attempts++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryEC2 = %s, want nil", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestRetryEC2RetriesTransientErrorThenSucceeds(t *testing.T) {
+	tr := newRetryTestTracker()
+	attempts := 0
+	err := tr.retryEC2("CreateRoute", func(ctx aws.Context) error {
+		attempts++
+		if attempts < 3 {
+			return awserr.New("Throttling", "slow down", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryEC2 = %s, want nil", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestRetryEC2ExhaustsAttemptsOnPersistentTransientError(t *testing.T) {
+	tr := newRetryTestTracker()
+	attempts := 0
+	err := tr.retryEC2("CreateRoute", func(ctx aws.Context) error {
+		attempts++
+		return awserr.New("Throttling", "slow down", nil)
+	})
+	if attempts != retryMaxAttempts {
+		t.Errorf("attempts = %d, want %d", attempts, retryMaxAttempts)
+	}
+	if err == nil || !strings.Contains(err.Error(), "failed after") {
+		t.Errorf("retryEC2 error = %v, want it to report attempts exhausted", err)
+	}
+}
+
+func TestRetryEC2DoesNotRetryNonRetryableError(t *testing.T) {
+	tr := newRetryTestTracker()
+	attempts := 0
+	wantErr := awserr.New(routeAlreadyExistsCode, "already exists", nil)
+	err := tr.retryEC2("CreateRoute", func(ctx aws.Context) error {
+		attempts++
+		return wantErr
+	})
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+	if err != wantErr {
+		t.Errorf("retryEC2 error = %v, want the original error returned unwrapped so callers can still type-assert on its code", err)
+	}
+}
+
+func TestRetryEC2SurfacesAuthErrorImmediately(t *testing.T) {
+	tr := newRetryTestTracker()
+	attempts := 0
+	err := tr.retryEC2("CreateRoute", func(ctx aws.Context) error {
+		attempts++
+		return awserr.New("UnauthorizedOperation", "nope", nil)
+	})
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+	if err == nil || !strings.Contains(err.Error(), requiredIAMActions["CreateRoute"]) {
+		t.Errorf("retryEC2 error = %v, want it to mention %s", err, requiredIAMActions["CreateRoute"])
+	}
+}
+
+func TestRetryEC2RetriesOnRequestCanceled(t *testing.T) {
+	tr := newRetryTestTracker()
+	attempts := 0
+	err := tr.retryEC2("CreateRoute", func(ctx aws.Context) error {
+		attempts++
+		if attempts < 2 {
+			return awserr.New("RequestCanceled", "context deadline exceeded", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryEC2 = %s, want nil", err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestRetryEC2Retries5xxRequestFailure(t *testing.T) {
+	tr := newRetryTestTracker()
+	attempts := 0
+	err := tr.retryEC2("CreateRoute", func(ctx aws.Context) error {
+		attempts++
+		if attempts < 2 {
+			return awserr.NewRequestFailure(awserr.New("ServiceUnavailable", "try again", nil), 503, "req-1")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryEC2 = %s, want nil", err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestRetryEC2ReturnsNonAwsErrorImmediately(t *testing.T) {
+	tr := newRetryTestTracker()
+	attempts := 0
+	plainErr := errors.New("boom")
+	err := tr.retryEC2("CreateRoute", func(ctx aws.Context) error {
+		attempts++
+		return plainErr
+	})
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+	if err != plainErr {
+		t.Errorf("retryEC2 error = %v, want %v", err, plainErr)
+	}
+}